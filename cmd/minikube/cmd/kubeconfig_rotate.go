@@ -0,0 +1,70 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+)
+
+var (
+	rotateThreshold time.Duration
+	rotateProfile   string
+)
+
+// kubeconfigRotateCmd rotates the client certificate backing the minikube
+// context if it is nearing expiry.
+var kubeconfigRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate the minikube client certificate if it is close to expiring",
+	Long:  "Inspects the client certificate used by the minikube kubeconfig context and, if less than the given threshold of its validity remains, generates a new one signed by the local CA and rewrites the kubeconfig in place.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := &kubeconfig.KubeConfigSetup{
+			CertificateAuthority: filepath.Join(constants.Minipath, "ca.crt"),
+		}
+		if rotateProfile == "" {
+			cfg.ClusterName = "minikube"
+		} else {
+			cfg.Profile = rotateProfile
+		}
+
+		rotated, err := kubeconfig.RotateClientCert(cfg, rotateThreshold)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rotating client certificate: %v\n", err)
+			os.Exit(1)
+		}
+
+		if rotated {
+			fmt.Println("Client certificate rotated.")
+		} else {
+			fmt.Println("Client certificate does not need rotation yet.")
+		}
+	},
+}
+
+func init() {
+	kubeconfigRotateCmd.Flags().DurationVar(&rotateThreshold, "threshold", kubeconfig.DefaultRotationThreshold, "Rotate the client certificate once less than this much of its validity period remains")
+	kubeconfigRotateCmd.Flags().StringVar(&rotateProfile, "profile", "", "The minikube profile whose client certificate should be rotated (defaults to the unnamed \"minikube\" context)")
+	kubeconfigCmd.AddCommand(kubeconfigRotateCmd)
+}
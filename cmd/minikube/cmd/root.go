@@ -0,0 +1,41 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cmd implements the minikube command line tool's subcommands.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// RootCmd is the root command of the minikube CLI, to which every
+// subcommand in this package attaches itself via init().
+var RootCmd = &cobra.Command{
+	Use:   "minikube",
+	Short: "Minikube is a tool for managing local Kubernetes clusters.",
+	Long:  `Minikube is a CLI tool that provisions and manages a single-node Kubernetes cluster inside a VM or container runtime on your local machine.`,
+}
+
+// Execute adds all child commands to the root command and runs it.
+func Execute() {
+	if err := RootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package constants
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// APIServerPort is the port that the API server should listen on.
+	APIServerPort = 8443
+)
+
+// Minikube home folder goes here
+var Minipath = filepath.Join(homeDir(), ".minikube")
+
+// KubeconfigPath is the path to the Kubernetes client config
+var KubeconfigPath = filepath.Join(homeDir(), ".kube", "config")
+
+func homeDir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+	return os.Getenv("USERPROFILE")
+}
@@ -23,7 +23,7 @@ import (
 	"strconv"
 	"testing"
 
-	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd/api"
+	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/minikube/pkg/minikube/constants"
 )
 
@@ -91,6 +91,45 @@ func TestSetupKubeConfig(t *testing.T) {
 			},
 			existingCfg: fakeKubeCfg,
 		},
+		{
+			description: "exec credential plugin",
+			cfg: &KubeConfigSetup{
+				ClusterName:          "test",
+				ClusterServerAddress: "192.168.1.1:8080",
+				CertificateAuthority: "/home/apiserver.crt",
+				ExecCredential: &api.ExecConfig{
+					Command:    "gke-gcloud-auth-plugin",
+					Args:       []string{"get-credentials"},
+					APIVersion: "client.authentication.k8s.io/v1beta1",
+					Env:        []api.ExecEnvVar{{Name: "CLOUDSDK_CORE_PROJECT", Value: "my-project"}},
+				},
+			},
+		},
+		{
+			description: "named auth-provider",
+			cfg: &KubeConfigSetup{
+				ClusterName:          "test",
+				ClusterServerAddress: "192.168.1.1:8080",
+				CertificateAuthority: "/home/apiserver.crt",
+				AuthProviderConfig: &api.AuthProviderConfig{
+					Name:   "gcp",
+					Config: map[string]string{"scopes": "https://www.googleapis.com/auth/cloud-platform"},
+				},
+			},
+		},
+		{
+			description: "switch from static certs to exec plugin",
+			cfg: &KubeConfigSetup{
+				ClusterName:          "test",
+				ClusterServerAddress: "192.168.1.1:8080",
+				CertificateAuthority: "/home/apiserver.crt",
+				ExecCredential: &api.ExecConfig{
+					Command:    "oidc-login",
+					APIVersion: "client.authentication.k8s.io/v1beta1",
+				},
+			},
+			existingCfg: fakeKubeCfg,
+		},
 	}
 
 	for _, test := range tests {
@@ -173,8 +212,75 @@ func TestNewConfig(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if !configEquals(actual, expected) {
-		t.Fatal("configs did not match")
+	if !Equal(actual, expected) {
+		t.Fatalf("configs did not match: %v", Diff(actual, expected))
+	}
+}
+
+// TestAuthInfoRoundTrip verifies that switching a context between static
+// client certs, an exec credential plugin, and a named auth-provider
+// round-trips cleanly through WriteConfig/ReadConfigOrNew.
+func TestAuthInfoRoundTrip(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("Error making temp directory %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	kubeConfigFile := filepath.Join(tmpDir, "kubeconfig")
+
+	base := &KubeConfigSetup{
+		ClusterName:          "test",
+		ClusterServerAddress: "192.168.1.1:8080",
+		CertificateAuthority: "/home/apiserver.crt",
+		KubeConfigFile:       kubeConfigFile,
+	}
+
+	static := *base
+	static.ClientCertificate = "/home/apiserver.crt"
+	static.ClientKey = "/home/apiserver.key"
+	if err := SetupKubeConfig(&static); err != nil {
+		t.Fatalf("SetupKubeConfig(static): %v", err)
+	}
+	config, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+	if config.AuthInfos["test"].ClientCertificate != static.ClientCertificate {
+		t.Errorf("expected static client certificate to round-trip")
+	}
+
+	exec := *base
+	exec.ExecCredential = &api.ExecConfig{Command: "aws", Args: []string{"eks", "get-token"}, APIVersion: "client.authentication.k8s.io/v1beta1"}
+	if err := SetupKubeConfig(&exec); err != nil {
+		t.Fatalf("SetupKubeConfig(exec): %v", err)
+	}
+	config, err = ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+	authInfo := config.AuthInfos["test"]
+	if !execConfigEqual(authInfo.Exec, exec.ExecCredential) {
+		t.Errorf("expected exec credential to round-trip, got %+v", authInfo.Exec)
+	}
+	if authInfo.ClientCertificate != "" {
+		t.Errorf("expected switching to exec mode to clear the static client certificate, got %q", authInfo.ClientCertificate)
+	}
+
+	authProvider := *base
+	authProvider.AuthProviderConfig = &api.AuthProviderConfig{Name: "oidc", Config: map[string]string{"idp-issuer-url": "https://issuer.example.com"}}
+	if err := SetupKubeConfig(&authProvider); err != nil {
+		t.Fatalf("SetupKubeConfig(auth-provider): %v", err)
+	}
+	config, err = ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+	authInfo = config.AuthInfos["test"]
+	if !authProviderEqual(authInfo.AuthProvider, authProvider.AuthProviderConfig) {
+		t.Errorf("expected auth-provider config to round-trip, got %+v", authInfo.AuthProvider)
+	}
+	if authInfo.Exec != nil {
+		t.Errorf("expected switching to auth-provider mode to clear the exec config, got %+v", authInfo.Exec)
 	}
 }
 
@@ -224,85 +330,3 @@ func minikubeConfig(config *api.Config) {
 
 	config.CurrentContext = contextName
 }
-
-// configEquals checks if configs are identical
-func configEquals(a, b *api.Config) bool {
-	if a.Kind != b.Kind {
-		return false
-	}
-
-	if a.APIVersion != b.APIVersion {
-		return false
-	}
-
-	if a.Preferences.Colors != b.Preferences.Colors {
-		return false
-	}
-	if len(a.Extensions) != len(b.Extensions) {
-		return false
-	}
-
-	// clusters
-	if len(a.Clusters) != len(b.Clusters) {
-		return false
-	}
-	for k, aCluster := range a.Clusters {
-		bCluster, exists := b.Clusters[k]
-		if !exists {
-			return false
-		}
-
-		if aCluster.LocationOfOrigin != bCluster.LocationOfOrigin ||
-			aCluster.Server != bCluster.Server ||
-			aCluster.APIVersion != bCluster.APIVersion ||
-			aCluster.InsecureSkipTLSVerify != bCluster.InsecureSkipTLSVerify ||
-			aCluster.CertificateAuthority != bCluster.CertificateAuthority ||
-			len(aCluster.CertificateAuthorityData) != len(bCluster.CertificateAuthorityData) ||
-			len(aCluster.Extensions) != len(bCluster.Extensions) {
-			return false
-		}
-	}
-
-	// users
-	if len(a.AuthInfos) != len(b.AuthInfos) {
-		return false
-	}
-	for k, aAuth := range a.AuthInfos {
-		bAuth, exists := b.AuthInfos[k]
-		if !exists {
-			return false
-		}
-		if aAuth.LocationOfOrigin != bAuth.LocationOfOrigin ||
-			aAuth.ClientCertificate != bAuth.ClientCertificate ||
-			len(aAuth.ClientCertificateData) != len(bAuth.ClientCertificateData) ||
-			aAuth.ClientKey != bAuth.ClientKey ||
-			len(aAuth.ClientKeyData) != len(bAuth.ClientKeyData) ||
-			aAuth.Token != bAuth.Token ||
-			aAuth.Username != bAuth.Username ||
-			aAuth.Password != bAuth.Password ||
-			len(aAuth.Extensions) != len(bAuth.Extensions) {
-			return false
-		}
-
-	}
-
-	// contexts
-	if len(a.Contexts) != len(b.Contexts) {
-		return false
-	}
-	for k, aContext := range a.Contexts {
-		bContext, exists := b.Contexts[k]
-		if !exists {
-			return false
-		}
-		if aContext.LocationOfOrigin != bContext.LocationOfOrigin ||
-			aContext.Cluster != bContext.Cluster ||
-			aContext.AuthInfo != bContext.AuthInfo ||
-			aContext.Namespace != bContext.Namespace ||
-			len(aContext.Extensions) != len(bContext.Extensions) {
-			return false
-		}
-
-	}
-	return true
-}
@@ -0,0 +1,298 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Difference describes one field that differs between two configs, at the
+// path returned by Diff (e.g. "clusters[minikube].Server").
+type Difference struct {
+	Path string
+	A    interface{}
+	B    interface{}
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %#v != %#v", d.Path, d.A, d.B)
+}
+
+// diffSet accumulates Differences as they're found.
+type diffSet []Difference
+
+func (d *diffSet) add(path string, a, b interface{}) {
+	*d = append(*d, Difference{Path: path, A: a, B: b})
+}
+
+// Equal reports whether a and b describe the same kubeconfig content, field
+// by field. Unlike a byte-for-byte comparison of the encoded YAML, map and
+// extension ordering don't matter.
+func Equal(a, b *api.Config) bool {
+	return len(Diff(a, b)) == 0
+}
+
+// Diff returns the field-level differences between a and b in the fields
+// compared below. Exec configs are compared by Command, Args, Env and
+// APIVersion only; their other fields are not compared. A nil result means
+// no difference was found in the compared fields.
+func Diff(a, b *api.Config) []Difference {
+	var d diffSet
+
+	if a.Kind != b.Kind {
+		d.add("Kind", a.Kind, b.Kind)
+	}
+	if a.APIVersion != b.APIVersion {
+		d.add("APIVersion", a.APIVersion, b.APIVersion)
+	}
+	if a.CurrentContext != b.CurrentContext {
+		d.add("CurrentContext", a.CurrentContext, b.CurrentContext)
+	}
+	if a.Preferences.Colors != b.Preferences.Colors {
+		d.add("Preferences.Colors", a.Preferences.Colors, b.Preferences.Colors)
+	}
+	diffExtensions(&d, "Preferences.Extensions", a.Preferences.Extensions, b.Preferences.Extensions)
+	diffExtensions(&d, "Extensions", a.Extensions, b.Extensions)
+
+	diffClusters(&d, a.Clusters, b.Clusters)
+	diffAuthInfos(&d, a.AuthInfos, b.AuthInfos)
+	diffContexts(&d, a.Contexts, b.Contexts)
+
+	return d
+}
+
+func diffClusters(d *diffSet, a, b map[string]*api.Cluster) {
+	for name, ac := range a {
+		bc, exists := b[name]
+		path := fmt.Sprintf("clusters[%s]", name)
+		if !exists {
+			d.add(path, ac, nil)
+			continue
+		}
+
+		if ac.LocationOfOrigin != bc.LocationOfOrigin {
+			d.add(path+".LocationOfOrigin", ac.LocationOfOrigin, bc.LocationOfOrigin)
+		}
+		if ac.Server != bc.Server {
+			d.add(path+".Server", ac.Server, bc.Server)
+		}
+		if ac.APIVersion != bc.APIVersion {
+			d.add(path+".APIVersion", ac.APIVersion, bc.APIVersion)
+		}
+		if ac.InsecureSkipTLSVerify != bc.InsecureSkipTLSVerify {
+			d.add(path+".InsecureSkipTLSVerify", ac.InsecureSkipTLSVerify, bc.InsecureSkipTLSVerify)
+		}
+		if ac.TLSServerName != bc.TLSServerName {
+			d.add(path+".TLSServerName", ac.TLSServerName, bc.TLSServerName)
+		}
+		if ac.CertificateAuthority != bc.CertificateAuthority {
+			d.add(path+".CertificateAuthority", ac.CertificateAuthority, bc.CertificateAuthority)
+		}
+		if !bytes.Equal(ac.CertificateAuthorityData, bc.CertificateAuthorityData) {
+			d.add(path+".CertificateAuthorityData", ac.CertificateAuthorityData, bc.CertificateAuthorityData)
+		}
+		if ac.ProxyURL != bc.ProxyURL {
+			d.add(path+".ProxyURL", ac.ProxyURL, bc.ProxyURL)
+		}
+		diffExtensions(d, path+".Extensions", ac.Extensions, bc.Extensions)
+	}
+
+	for name, bc := range b {
+		if _, exists := a[name]; !exists {
+			d.add(fmt.Sprintf("clusters[%s]", name), nil, bc)
+		}
+	}
+}
+
+func diffAuthInfos(d *diffSet, a, b map[string]*api.AuthInfo) {
+	for name, aa := range a {
+		ba, exists := b[name]
+		path := fmt.Sprintf("users[%s]", name)
+		if !exists {
+			d.add(path, aa, nil)
+			continue
+		}
+
+		if aa.LocationOfOrigin != ba.LocationOfOrigin {
+			d.add(path+".LocationOfOrigin", aa.LocationOfOrigin, ba.LocationOfOrigin)
+		}
+		if aa.ClientCertificate != ba.ClientCertificate {
+			d.add(path+".ClientCertificate", aa.ClientCertificate, ba.ClientCertificate)
+		}
+		if !bytes.Equal(aa.ClientCertificateData, ba.ClientCertificateData) {
+			d.add(path+".ClientCertificateData", aa.ClientCertificateData, ba.ClientCertificateData)
+		}
+		if aa.ClientKey != ba.ClientKey {
+			d.add(path+".ClientKey", aa.ClientKey, ba.ClientKey)
+		}
+		if !bytes.Equal(aa.ClientKeyData, ba.ClientKeyData) {
+			d.add(path+".ClientKeyData", aa.ClientKeyData, ba.ClientKeyData)
+		}
+		if aa.Token != ba.Token {
+			d.add(path+".Token", aa.Token, ba.Token)
+		}
+		if aa.TokenFile != ba.TokenFile {
+			d.add(path+".TokenFile", aa.TokenFile, ba.TokenFile)
+		}
+		if aa.Impersonate != ba.Impersonate {
+			d.add(path+".Impersonate", aa.Impersonate, ba.Impersonate)
+		}
+		if !stringSlicesEqual(aa.ImpersonateGroups, ba.ImpersonateGroups) {
+			d.add(path+".ImpersonateGroups", aa.ImpersonateGroups, ba.ImpersonateGroups)
+		}
+		if !stringSliceMapsEqual(aa.ImpersonateUserExtra, ba.ImpersonateUserExtra) {
+			d.add(path+".ImpersonateUserExtra", aa.ImpersonateUserExtra, ba.ImpersonateUserExtra)
+		}
+		if aa.Username != ba.Username {
+			d.add(path+".Username", aa.Username, ba.Username)
+		}
+		if aa.Password != ba.Password {
+			d.add(path+".Password", aa.Password, ba.Password)
+		}
+		if !execConfigEqual(aa.Exec, ba.Exec) {
+			d.add(path+".Exec", aa.Exec, ba.Exec)
+		}
+		if !authProviderEqual(aa.AuthProvider, ba.AuthProvider) {
+			d.add(path+".AuthProvider", aa.AuthProvider, ba.AuthProvider)
+		}
+		diffExtensions(d, path+".Extensions", aa.Extensions, ba.Extensions)
+	}
+
+	for name, ba := range b {
+		if _, exists := a[name]; !exists {
+			d.add(fmt.Sprintf("users[%s]", name), nil, ba)
+		}
+	}
+}
+
+func diffContexts(d *diffSet, a, b map[string]*api.Context) {
+	for name, ac := range a {
+		bc, exists := b[name]
+		path := fmt.Sprintf("contexts[%s]", name)
+		if !exists {
+			d.add(path, ac, nil)
+			continue
+		}
+
+		if ac.LocationOfOrigin != bc.LocationOfOrigin {
+			d.add(path+".LocationOfOrigin", ac.LocationOfOrigin, bc.LocationOfOrigin)
+		}
+		if ac.Cluster != bc.Cluster {
+			d.add(path+".Cluster", ac.Cluster, bc.Cluster)
+		}
+		if ac.AuthInfo != bc.AuthInfo {
+			d.add(path+".AuthInfo", ac.AuthInfo, bc.AuthInfo)
+		}
+		if ac.Namespace != bc.Namespace {
+			d.add(path+".Namespace", ac.Namespace, bc.Namespace)
+		}
+		diffExtensions(d, path+".Extensions", ac.Extensions, bc.Extensions)
+	}
+
+	for name, bc := range b {
+		if _, exists := a[name]; !exists {
+			d.add(fmt.Sprintf("contexts[%s]", name), nil, bc)
+		}
+	}
+}
+
+// diffExtensions compares two Extensions maps key by key. runtime.Object
+// values can't be compared with !=, so each entry is compared with
+// reflect.DeepEqual instead of just checking map length.
+func diffExtensions(d *diffSet, path string, a, b map[string]runtime.Object) {
+	if len(a) != len(b) {
+		d.add(path, a, b)
+		return
+	}
+	for k, av := range a {
+		bv, exists := b[k]
+		if !exists || !reflect.DeepEqual(av, bv) {
+			d.add(path, a, b)
+			return
+		}
+	}
+}
+
+func execConfigEqual(a, b *api.ExecConfig) bool {
+	if (a == nil) != (b == nil) {
+		return false
+	}
+	if a == nil {
+		return true
+	}
+	if a.Command != b.Command || a.APIVersion != b.APIVersion || len(a.Args) != len(b.Args) || len(a.Env) != len(b.Env) {
+		return false
+	}
+	for i := range a.Args {
+		if a.Args[i] != b.Args[i] {
+			return false
+		}
+	}
+	for i := range a.Env {
+		if a.Env[i] != b.Env[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func authProviderEqual(a, b *api.AuthProviderConfig) bool {
+	if (a == nil) != (b == nil) {
+		return false
+	}
+	if a == nil {
+		return true
+	}
+	if a.Name != b.Name || len(a.Config) != len(b.Config) {
+		return false
+	}
+	for k, v := range a.Config {
+		if b.Config[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func stringSliceMapsEqual(a, b map[string][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !stringSlicesEqual(v, b[k]) {
+			return false
+		}
+	}
+	return true
+}
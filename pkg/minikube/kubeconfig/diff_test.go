@@ -0,0 +1,213 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"os"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func twoClusterConfigs() (*api.Config, *api.Config) {
+	a := api.NewConfig()
+	a.Clusters["minikube"] = api.NewCluster()
+	a.Clusters["minikube"].CertificateAuthorityData = []byte("aaaaaaaaaaaaaaaa")
+
+	b := api.NewConfig()
+	b.Clusters["minikube"] = api.NewCluster()
+	b.Clusters["minikube"].CertificateAuthorityData = []byte("bbbbbbbbbbbbbbbb")
+
+	return a, b
+}
+
+func TestEqualSameConfig(t *testing.T) {
+	a := api.NewConfig()
+	minikubeConfig(a)
+	b := api.NewConfig()
+	minikubeConfig(b)
+
+	if !Equal(a, b) {
+		t.Fatalf("expected identical configs to be equal, got diff: %v", Diff(a, b))
+	}
+}
+
+// TestEqualDetectsSameLengthCertificateAuthorityData ensures Diff compares
+// the actual bytes of CertificateAuthorityData, not just its length: two
+// configs with equal-length but different CA data must not be Equal.
+func TestEqualDetectsSameLengthCertificateAuthorityData(t *testing.T) {
+	a, b := twoClusterConfigs()
+
+	if len(a.Clusters["minikube"].CertificateAuthorityData) != len(b.Clusters["minikube"].CertificateAuthorityData) {
+		t.Fatal("test fixture bug: CertificateAuthorityData must be equal length")
+	}
+
+	if Equal(a, b) {
+		t.Fatal("expected configs with different CertificateAuthorityData content to not be equal")
+	}
+
+	diffs := Diff(a, b)
+	found := false
+	for _, d := range diffs {
+		if d.Path == "clusters[minikube].CertificateAuthorityData" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a CertificateAuthorityData diff, got %v", diffs)
+	}
+}
+
+// TestEqualDetectsSameLengthExtensionContent ensures diffExtensions compares
+// entry content, not just map length: two configs whose Extensions have the
+// same keys but different values must not be Equal.
+func TestEqualDetectsSameLengthExtensionContent(t *testing.T) {
+	a := api.NewConfig()
+	a.Extensions["note"] = &runtime.Unknown{RawJSON: []byte("one")}
+
+	b := api.NewConfig()
+	b.Extensions["note"] = &runtime.Unknown{RawJSON: []byte("two")}
+
+	if len(a.Extensions) != len(b.Extensions) {
+		t.Fatal("test fixture bug: Extensions must be equal length")
+	}
+
+	if Equal(a, b) {
+		t.Fatal("expected configs with different Extensions content to not be equal")
+	}
+
+	diffs := Diff(a, b)
+	found := false
+	for _, d := range diffs {
+		if d.Path == "Extensions" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected an Extensions diff, got %v", diffs)
+	}
+}
+
+func TestDiffDetectsExecAndAuthProviderDifferences(t *testing.T) {
+	a := api.NewConfig()
+	a.AuthInfos["minikube"] = api.NewAuthInfo()
+	a.AuthInfos["minikube"].Exec = &api.ExecConfig{Command: "a"}
+
+	b := api.NewConfig()
+	b.AuthInfos["minikube"] = api.NewAuthInfo()
+	b.AuthInfos["minikube"].AuthProvider = &api.AuthProviderConfig{Name: "gcp"}
+
+	if Equal(a, b) {
+		t.Fatal("expected exec vs auth-provider configs to differ")
+	}
+}
+
+// TestDiffDetectsClusterProxyURL confirms Cluster.ProxyURL, a client-go-only
+// field absent from the legacy clientcmd/api package, participates in Diff.
+func TestDiffDetectsClusterProxyURL(t *testing.T) {
+	a := api.NewConfig()
+	a.Clusters["minikube"] = api.NewCluster()
+	a.Clusters["minikube"].ProxyURL = "http://proxy.example.com:8080"
+
+	b := api.NewConfig()
+	b.Clusters["minikube"] = api.NewCluster()
+
+	if Equal(a, b) {
+		t.Fatal("expected configs with different Cluster.ProxyURL to not be equal")
+	}
+}
+
+// TestDiffDetectsAuthInfoImpersonation confirms the AuthInfo
+// TokenFile/Impersonate/ImpersonateGroups/ImpersonateUserExtra fields, all
+// client-go-only, participate in Diff.
+func TestDiffDetectsAuthInfoImpersonation(t *testing.T) {
+	tests := []struct {
+		name string
+		mod  func(*api.AuthInfo)
+	}{
+		{"TokenFile", func(a *api.AuthInfo) { a.TokenFile = "/var/run/secrets/token" }},
+		{"Impersonate", func(a *api.AuthInfo) { a.Impersonate = "admin" }},
+		{"ImpersonateGroups", func(a *api.AuthInfo) { a.ImpersonateGroups = []string{"system:masters"} }},
+		{"ImpersonateUserExtra", func(a *api.AuthInfo) { a.ImpersonateUserExtra = map[string][]string{"scopes": {"view"}} }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := api.NewConfig()
+			a.AuthInfos["minikube"] = api.NewAuthInfo()
+
+			b := api.NewConfig()
+			b.AuthInfos["minikube"] = api.NewAuthInfo()
+			test.mod(b.AuthInfos["minikube"])
+
+			if Equal(a, b) {
+				t.Fatalf("expected configs differing only in AuthInfo.%s to not be equal", test.name)
+			}
+		})
+	}
+}
+
+func TestSetupKubeConfigSkipsWriteWhenUnchanged(t *testing.T) {
+	tmpDir := tempFile(t, []byte{})
+	defer os.Remove(tmpDir)
+
+	cfg := &KubeConfigSetup{
+		ClusterName:          "minikube",
+		ClusterServerAddress: "https://192.168.99.100:8443",
+		ClientCertificate:    "/home/tux/.minikube/apiserver.crt",
+		ClientKey:            "/home/tux/.minikube/apiserver.key",
+		CertificateAuthority: "/home/tux/.minikube/apiserver.crt",
+		KubeConfigFile:       tmpDir,
+	}
+
+	// Seed the file with exactly what this call would produce.
+	if err := SetupKubeConfig(cfg); err != nil {
+		t.Fatalf("seeding SetupKubeConfig: %v", err)
+	}
+	before, err := ReadConfigOrNew(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+	beforeInfo, err := os.Stat(tmpDir)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+
+	if err := SetupKubeConfig(cfg); err != nil {
+		t.Fatalf("SetupKubeConfig: %v", err)
+	}
+
+	// WriteConfig renames a new file into place, so a skipped write leaves
+	// the very same file behind.
+	afterInfo, err := os.Stat(tmpDir)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if !os.SameFile(beforeInfo, afterInfo) {
+		t.Fatal("expected a no-op SetupKubeConfig to skip rewriting the kubeconfig file")
+	}
+
+	after, err := ReadConfigOrNew(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+
+	if !Equal(before, after) {
+		t.Fatalf("expected a no-op SetupKubeConfig to leave the config unchanged: %v", Diff(before, after))
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// KubeConfigSetup is a soft layer on top of the kubeconfig file that captures
+// the values we want to set for a minikube context.
+//
+// Credentials can be provided in one of three mutually exclusive ways: a
+// static client certificate/key pair, an exec credential plugin, or a named
+// auth-provider. Setting more than one leaves the others nil in the written
+// AuthInfo, matching how client-go itself treats these as alternatives.
+//
+// This package uses k8s.io/client-go/tools/clientcmd/api rather than the
+// older, frozen k8s.io/kubernetes/pkg/client/unversioned/clientcmd/api:
+// ExecConfig, AuthProviderConfig, Cluster.ProxyURL and the AuthInfo
+// impersonation/TokenFile fields only exist on the client-go type.
+type KubeConfigSetup struct {
+	// The name of the cluster for this context
+	ClusterName string
+
+	// Profile is the name of the minikube profile (VM) this context belongs
+	// to. When set, the cluster/user/context triple is named
+	// "minikube-<profile>" instead of ClusterName, so that multiple profiles
+	// (e.g. "dev", "test", "ci") can coexist as separate contexts in the same
+	// kubeconfig file. Leave empty to address a single, unnamed minikube
+	// context by ClusterName, matching minikube's historical behavior.
+	Profile string
+
+	// ClusterServerAddress is the address of the Kubernetes cluster
+	ClusterServerAddress string
+
+	// ClientCertificate is the path to a client cert file for TLS.
+	ClientCertificate string
+
+	// CertificateAuthority is the path to a cert file for the certificate authority.
+	CertificateAuthority string
+
+	// ClientKey is the path to a client key file for TLS.
+	ClientKey string
+
+	// ExecCredential, if set, configures the user to fetch credentials from an
+	// external exec plugin (e.g. a cloud provider's token helper) instead of a
+	// static client certificate.
+	ExecCredential *api.ExecConfig
+
+	// AuthProviderConfig, if set, configures the user to authenticate via a
+	// named auth-provider plugin (e.g. gcp, oidc) instead of a static client
+	// certificate.
+	AuthProviderConfig *api.AuthProviderConfig
+
+	// KeepContext is true if the user wants to keep the their current kubectl context,
+	// in which case minikube's context is merged in but not set as current.
+	KeepContext bool
+
+	// KubeConfigFile is the path to the kube config file, defaults to constants.KubeconfigPath
+	KubeConfigFile string
+}
+
+// SetKubeConfigFile sets the path of the kube config file
+func (k *KubeConfigSetup) SetKubeConfigFile(configFile string) {
+	k.KubeConfigFile = configFile
+}
+
+// GetKubeConfigFile returns the path of the kube config file, falling back to the default.
+func (k KubeConfigSetup) GetKubeConfigFile() string {
+	if k.KubeConfigFile == "" {
+		return constants.KubeconfigPath
+	}
+	return k.KubeConfigFile
+}
+
+// contextName returns the name used for this setup's cluster, user and
+// context entries: "minikube-<profile>" when a Profile is set, or
+// ClusterName otherwise.
+func (k KubeConfigSetup) contextName() string {
+	if k.Profile != "" {
+		return "minikube-" + k.Profile
+	}
+	return k.ClusterName
+}
+
+// PopulateKubeConfig populates an api.Config object with the cluster, user and
+// context described by kcs, so it can be merged into an existing config.
+func PopulateKubeConfig(kcs *KubeConfigSetup, config *api.Config) {
+	name := kcs.contextName()
+
+	cluster := api.NewCluster()
+	cluster.Server = kcs.ClusterServerAddress
+	cluster.CertificateAuthority = kcs.CertificateAuthority
+	config.Clusters[name] = cluster
+
+	// user
+	user := api.NewAuthInfo()
+	switch {
+	case kcs.ExecCredential != nil:
+		user.Exec = kcs.ExecCredential
+	case kcs.AuthProviderConfig != nil:
+		user.AuthProvider = kcs.AuthProviderConfig
+	default:
+		user.ClientCertificate = kcs.ClientCertificate
+		user.ClientKey = kcs.ClientKey
+	}
+	config.AuthInfos[name] = user
+
+	// context
+	context := api.NewContext()
+	context.Cluster = name
+	context.AuthInfo = name
+	config.Contexts[name] = context
+
+	if !kcs.KeepContext {
+		config.CurrentContext = name
+	}
+}
+
+// SetupKubeConfig reads config from disk, adds the minikube settings, and writes it back.
+func SetupKubeConfig(kcs *KubeConfigSetup) error {
+	configFile := kcs.GetKubeConfigFile()
+
+	config, err := ReadConfigOrNew(configFile)
+	if err != nil {
+		return err
+	}
+
+	name := kcs.contextName()
+	before := contextSnapshot(config, name)
+
+	PopulateKubeConfig(kcs, config)
+
+	// Concurrent `minikube status` invocations all call SetupKubeConfig; skip
+	// the write (and its mtime churn) if our context already matches.
+	if Equal(before, contextSnapshot(config, name)) {
+		return nil
+	}
+
+	return WriteConfig(config, configFile)
+}
+
+// contextSnapshot extracts just the named cluster/user/context triple (plus
+// CurrentContext) from config, for use with Equal/Diff to detect whether
+// SetupKubeConfig actually needs to change anything.
+func contextSnapshot(config *api.Config, name string) *api.Config {
+	snapshot := api.NewConfig()
+	snapshot.CurrentContext = config.CurrentContext
+	if cluster, ok := config.Clusters[name]; ok {
+		c := *cluster
+		snapshot.Clusters[name] = &c
+	}
+	if user, ok := config.AuthInfos[name]; ok {
+		u := *user
+		snapshot.AuthInfos[name] = &u
+	}
+	if context, ok := config.Contexts[name]; ok {
+		c := *context
+		snapshot.Contexts[name] = &c
+	}
+	return snapshot
+}
+
+// ReadConfigOrNew retrieves Kubernetes client configuration from a file.
+// If no files exists, an empty configuration is returned.
+func ReadConfigOrNew(filename string) (*api.Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return api.NewConfig(), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading file %q: %v", filename, err)
+	}
+
+	config, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding config %q: %v", filename, err)
+	}
+
+	// initialize nil maps
+	if config.AuthInfos == nil {
+		config.AuthInfos = map[string]*api.AuthInfo{}
+	}
+	if config.Clusters == nil {
+		config.Clusters = map[string]*api.Cluster{}
+	}
+	if config.Contexts == nil {
+		config.Contexts = map[string]*api.Context{}
+	}
+
+	return config, nil
+}
+
+// WriteConfig encodes the configuration and writes it to the given file.
+// If the file exists, its contents will be overwritten.
+func WriteConfig(config *api.Config, filename string) error {
+	if config == nil {
+		return errors.New("could not write to disk, config is nil")
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return fmt.Errorf("could not write to %q: failed to encode config: %v", filename, err)
+	}
+
+	dir := filepath.Dir(filename)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return atomicWriteFile(filename, data, 0600)
+}
+
+// atomicWriteFile writes data to a temp file in the same directory as
+// filename and renames it into place, so readers never observe a partially
+// written kubeconfig.
+func atomicWriteFile(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+func decode(data []byte) (*api.Config, error) {
+	return clientcmd.Load(data)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minikubeContextPrefix is prepended to a profile name to form the
+// cluster/user/context name minikube uses for that profile, e.g. "dev"
+// becomes "minikube-dev".
+const minikubeContextPrefix = "minikube-"
+
+// ContextInfo describes one minikube context found in a kubeconfig file.
+type ContextInfo struct {
+	// ContextName is the full context name, e.g. "minikube-dev".
+	ContextName string
+
+	// Profile is the minikube profile the context was created for, e.g. "dev".
+	Profile string
+
+	// ServerAddress is the API server address the context's cluster points at.
+	ServerAddress string
+}
+
+// ListMinikubeContexts returns every context in kubeConfigFile that was
+// created by SetupKubeConfig, i.e. named "minikube" or "minikube-<profile>".
+func ListMinikubeContexts(kubeConfigFile string) ([]ContextInfo, error) {
+	config, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []ContextInfo
+	for name, context := range config.Contexts {
+		profile, ok := minikubeProfile(name)
+		if !ok {
+			continue
+		}
+
+		info := ContextInfo{ContextName: name, Profile: profile}
+		if cluster, ok := config.Clusters[context.Cluster]; ok {
+			info.ServerAddress = cluster.Server
+		}
+		contexts = append(contexts, info)
+	}
+
+	return contexts, nil
+}
+
+// minikubeProfile reports the profile name encoded in a minikube context
+// name, e.g. "minikube-dev" -> ("dev", true) and "minikube" -> ("minikube", true).
+func minikubeProfile(contextName string) (profile string, ok bool) {
+	if contextName == "minikube" {
+		return "minikube", true
+	}
+	if strings.HasPrefix(contextName, minikubeContextPrefix) {
+		return strings.TrimPrefix(contextName, minikubeContextPrefix), true
+	}
+	return "", false
+}
+
+// SetCurrentContext switches the current-context in kubeConfigFile to
+// contextName, without touching any clusters, users or other contexts. It is
+// the programmatic equivalent of `kubectl config use-context`.
+func SetCurrentContext(kubeConfigFile, contextName string) error {
+	config, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := config.Contexts[contextName]; !exists {
+		return fmt.Errorf("no context named %q in %s", contextName, kubeConfigFile)
+	}
+
+	config.CurrentContext = contextName
+	return WriteConfig(config, kubeConfigFile)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestMultipleProfiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("making temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	kubeConfigFile := filepath.Join(tmpDir, "kubeconfig")
+
+	profiles := []string{"dev", "test", "ci"}
+	for i, profile := range profiles {
+		cfg := &KubeConfigSetup{
+			Profile:              profile,
+			ClusterServerAddress: fmt.Sprintf("https://192.168.99.%d:8443", 100+i),
+			ClientCertificate:    fmt.Sprintf("/home/tux/.minikube/profiles/%s/apiserver.crt", profile),
+			ClientKey:            fmt.Sprintf("/home/tux/.minikube/profiles/%s/apiserver.key", profile),
+			CertificateAuthority: fmt.Sprintf("/home/tux/.minikube/profiles/%s/ca.crt", profile),
+			KubeConfigFile:       kubeConfigFile,
+		}
+		if err := SetupKubeConfig(cfg); err != nil {
+			t.Fatalf("SetupKubeConfig(%s): %v", profile, err)
+		}
+	}
+
+	contexts, err := ListMinikubeContexts(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ListMinikubeContexts: %v", err)
+	}
+	if len(contexts) != len(profiles) {
+		t.Fatalf("expected %d contexts, got %d: %+v", len(profiles), len(contexts), contexts)
+	}
+
+	config, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+	for i, profile := range profiles {
+		name := "minikube-" + profile
+		cluster, exists := config.Clusters[name]
+		if !exists {
+			t.Fatalf("expected cluster %q to exist", name)
+		}
+		wantAddr := fmt.Sprintf("https://192.168.99.%d:8443", 100+i)
+		if cluster.Server != wantAddr {
+			t.Errorf("cluster %q: got server %q, want %q", name, cluster.Server, wantAddr)
+		}
+
+		user, exists := config.AuthInfos[name]
+		if !exists {
+			t.Fatalf("expected user %q to exist", name)
+		}
+		wantCert := fmt.Sprintf("/home/tux/.minikube/profiles/%s/apiserver.crt", profile)
+		if user.ClientCertificate != wantCert {
+			t.Errorf("user %q: got client cert %q, want %q", name, user.ClientCertificate, wantCert)
+		}
+	}
+
+	// The most recently set up profile ("ci") should be current.
+	if config.CurrentContext != "minikube-ci" {
+		t.Errorf("expected current context to be minikube-ci, got %s", config.CurrentContext)
+	}
+
+	if err := SetCurrentContext(kubeConfigFile, "minikube-dev"); err != nil {
+		t.Fatalf("SetCurrentContext: %v", err)
+	}
+	config, err = ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+	if config.CurrentContext != "minikube-dev" {
+		t.Errorf("expected current context to be minikube-dev after switching, got %s", config.CurrentContext)
+	}
+	// Switching context must not disturb the other profiles.
+	if _, exists := config.Clusters["minikube-test"]; !exists {
+		t.Error("expected minikube-test cluster to survive a context switch")
+	}
+	if _, exists := config.Clusters["minikube-ci"]; !exists {
+		t.Error("expected minikube-ci cluster to survive a context switch")
+	}
+
+	if err := SetCurrentContext(kubeConfigFile, "minikube-nonexistent"); err == nil {
+		t.Error("expected an error switching to a context that doesn't exist")
+	}
+
+	names := make([]string, 0, len(contexts))
+	for _, c := range contexts {
+		names = append(names, c.ContextName)
+	}
+	sort.Strings(names)
+	want := []string{"minikube-ci", "minikube-dev", "minikube-test"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("ListMinikubeContexts: got %v, want %v", names, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// RemoveKubeConfig removes the named cluster, and any contexts and AuthInfos
+// that only exist to serve it, from kubeConfigFile. It is the inverse of
+// SetupKubeConfig.
+func RemoveKubeConfig(clusterName, kubeConfigFile string) error {
+	return PruneKubeConfig(kubeConfigFile, func(name string, cluster *api.Cluster) bool {
+		return name == clusterName
+	})
+}
+
+// PruneKubeConfig deletes every cluster in kubeConfigFile matched by
+// predicate, along with the contexts that point at those clusters and any
+// AuthInfos that only those contexts referenced. If CurrentContext pointed at
+// a removed context, it is reassigned to one of the remaining contexts, or
+// cleared if none remain.
+func PruneKubeConfig(kubeConfigFile string, predicate func(name string, cluster *api.Cluster) bool) error {
+	config, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		return err
+	}
+
+	removedClusters := map[string]bool{}
+	for name, cluster := range config.Clusters {
+		if predicate(name, cluster) {
+			removedClusters[name] = true
+			delete(config.Clusters, name)
+		}
+	}
+
+	if len(removedClusters) == 0 {
+		return nil
+	}
+
+	removedContexts := map[string]bool{}
+	orphanCandidates := map[string]bool{}
+	for name, context := range config.Contexts {
+		if removedClusters[context.Cluster] {
+			removedContexts[name] = true
+			orphanCandidates[context.AuthInfo] = true
+			delete(config.Contexts, name)
+		}
+	}
+
+	// Only AuthInfos referenced by a removed context are candidates for
+	// deletion, and only if no surviving context still uses them. AuthInfos
+	// that were already orphaned before this call, unrelated to what's being
+	// pruned, are left alone.
+	authInfosInUse := map[string]bool{}
+	for _, context := range config.Contexts {
+		authInfosInUse[context.AuthInfo] = true
+	}
+	for authInfoName := range orphanCandidates {
+		if !authInfosInUse[authInfoName] {
+			delete(config.AuthInfos, authInfoName)
+		}
+	}
+
+	if removedContexts[config.CurrentContext] {
+		config.CurrentContext = ""
+		for name := range config.Contexts {
+			config.CurrentContext = name
+			break
+		}
+	}
+
+	return WriteConfig(config, kubeConfigFile)
+}
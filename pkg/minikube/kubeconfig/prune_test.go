@@ -0,0 +1,203 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// seedConfig builds a config with two clusters that share a single AuthInfo
+// (as kubectl does for a shared token user) plus one cluster with its own
+// dedicated AuthInfo, and writes it to a temp kubeconfig file.
+func seedConfig(t *testing.T) (string, func()) {
+	t.Helper()
+
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("making temp dir: %v", err)
+	}
+
+	config := api.NewConfig()
+
+	config.Clusters["minikube"] = api.NewCluster()
+	config.Clusters["minikube-other"] = api.NewCluster()
+
+	sharedUser := api.NewAuthInfo()
+	sharedUser.Token = "shared-token"
+	config.AuthInfos["shared"] = sharedUser
+
+	dedicatedUser := api.NewAuthInfo()
+	dedicatedUser.ClientCertificate = "/home/minikube-other/cert.crt"
+	config.AuthInfos["minikube-other"] = dedicatedUser
+
+	ctxA := api.NewContext()
+	ctxA.Cluster = "minikube"
+	ctxA.AuthInfo = "shared"
+	config.Contexts["minikube"] = ctxA
+
+	ctxB := api.NewContext()
+	ctxB.Cluster = "minikube-other"
+	ctxB.AuthInfo = "minikube-other"
+	config.Contexts["minikube-other"] = ctxB
+
+	// A context that also uses the shared AuthInfo but belongs to a cluster
+	// we never prune, so "shared" must survive any single-cluster removal.
+	config.Clusters["keep"] = api.NewCluster()
+	ctxKeep := api.NewContext()
+	ctxKeep.Cluster = "keep"
+	ctxKeep.AuthInfo = "shared"
+	config.Contexts["keep"] = ctxKeep
+
+	config.CurrentContext = "minikube"
+
+	kubeConfigFile := filepath.Join(tmpDir, "kubeconfig")
+	if err := WriteConfig(config, kubeConfigFile); err != nil {
+		t.Fatalf("seeding kubeconfig: %v", err)
+	}
+
+	return kubeConfigFile, func() { os.RemoveAll(tmpDir) }
+}
+
+func TestRemoveKubeConfig(t *testing.T) {
+	kubeConfigFile, cleanup := seedConfig(t)
+	defer cleanup()
+
+	if err := RemoveKubeConfig("minikube", kubeConfigFile); err != nil {
+		t.Fatalf("RemoveKubeConfig: %v", err)
+	}
+
+	got, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+
+	if _, exists := got.Clusters["minikube"]; exists {
+		t.Error("expected cluster \"minikube\" to be removed")
+	}
+	if _, exists := got.Contexts["minikube"]; exists {
+		t.Error("expected context \"minikube\" to be removed")
+	}
+	if _, exists := got.AuthInfos["shared"]; !exists {
+		t.Error("expected AuthInfo \"shared\" to survive, it is still used by context \"keep\"")
+	}
+	if _, exists := got.Clusters["minikube-other"]; !exists {
+		t.Error("expected unrelated cluster \"minikube-other\" to be untouched")
+	}
+	if got.CurrentContext == "minikube" {
+		t.Error("expected CurrentContext to be reassigned away from the removed context")
+	}
+	if _, exists := got.Contexts[got.CurrentContext]; !exists {
+		t.Errorf("CurrentContext %q does not point at a remaining context", got.CurrentContext)
+	}
+}
+
+func TestPruneKubeConfigDeletesOrphanedAuthInfo(t *testing.T) {
+	kubeConfigFile, cleanup := seedConfig(t)
+	defer cleanup()
+
+	if err := RemoveKubeConfig("minikube-other", kubeConfigFile); err != nil {
+		t.Fatalf("RemoveKubeConfig: %v", err)
+	}
+
+	got, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+
+	if _, exists := got.AuthInfos["minikube-other"]; exists {
+		t.Error("expected AuthInfo \"minikube-other\" to be deleted, nothing else references it")
+	}
+}
+
+// TestPruneKubeConfigLeavesUnrelatedOrphanAlone verifies that an AuthInfo
+// which was already orphaned before the call, and never referenced by any
+// context tied to the pruned cluster, is left untouched. Pruning must only
+// reconsider AuthInfos that the removed contexts themselves referenced, not
+// sweep every orphaned AuthInfo in the file.
+func TestPruneKubeConfigLeavesUnrelatedOrphanAlone(t *testing.T) {
+	kubeConfigFile, cleanup := seedConfig(t)
+	defer cleanup()
+
+	config, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+	config.AuthInfos["stale"] = api.NewAuthInfo()
+	if err := WriteConfig(config, kubeConfigFile); err != nil {
+		t.Fatalf("seeding stale AuthInfo: %v", err)
+	}
+
+	if err := RemoveKubeConfig("minikube-other", kubeConfigFile); err != nil {
+		t.Fatalf("RemoveKubeConfig: %v", err)
+	}
+
+	got, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+
+	if _, exists := got.AuthInfos["stale"]; !exists {
+		t.Error("expected pre-existing orphaned AuthInfo \"stale\" to survive a prune of an unrelated cluster")
+	}
+}
+
+func TestPruneKubeConfigNoMatch(t *testing.T) {
+	kubeConfigFile, cleanup := seedConfig(t)
+	defer cleanup()
+
+	before, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+
+	err = PruneKubeConfig(kubeConfigFile, func(name string, cluster *api.Cluster) bool {
+		return name == "does-not-exist"
+	})
+	if err != nil {
+		t.Fatalf("PruneKubeConfig: %v", err)
+	}
+
+	after, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("ReadConfigOrNew: %v", err)
+	}
+
+	if !Equal(before, after) {
+		t.Errorf("expected a no-op prune to leave the config unchanged: %v", Diff(before, after))
+	}
+}
+
+func TestPruneKubeConfigEmptyFile(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("making temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	kubeConfigFile := filepath.Join(tmpDir, "kubeconfig")
+
+	err = PruneKubeConfig(kubeConfigFile, func(name string, cluster *api.Cluster) bool {
+		return true
+	})
+	if err != nil {
+		t.Fatalf("PruneKubeConfig on nonexistent file: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// DefaultRotationThreshold is how much absolute time may remain before
+// minikube proactively rotates a client certificate whose validity window is
+// the default one year. This mirrors the 20%-remaining-validity threshold
+// the Kubernetes control plane uses when automatically renewing
+// kubeadm-managed certificates. RotateClientCert compares the certificate's
+// remaining validity against the absolute threshold it is given, so for a
+// certificate whose lifetime is not one year this default is not 20% of
+// that lifetime.
+const DefaultRotationThreshold = 365 * 24 * time.Hour * 20 / 100
+
+// RotateClientCert inspects the client certificate backing cfg's minikube
+// context and, if less than threshold of its validity window remains,
+// generates a new key/cert pair signed by the CA at cfg.CertificateAuthority
+// and atomically rewrites the kubeconfig entry in place. Other contexts in
+// the file are left untouched.
+//
+// It returns rotated=false (and no error) if the certificate still has
+// plenty of life left.
+func RotateClientCert(cfg *KubeConfigSetup, threshold time.Duration) (rotated bool, err error) {
+	configFile := cfg.GetKubeConfigFile()
+
+	config, err := ReadConfigOrNew(configFile)
+	if err != nil {
+		return false, err
+	}
+
+	name := cfg.contextName()
+	authInfo, ok := config.AuthInfos[name]
+	if !ok {
+		return false, fmt.Errorf("no user %q in %s", name, configFile)
+	}
+
+	certPEM, err := clientCertPEM(authInfo)
+	if err != nil {
+		return false, err
+	}
+
+	cert, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return false, fmt.Errorf("parsing client certificate: %v", err)
+	}
+
+	if !needsRotation(cert, threshold) {
+		return false, nil
+	}
+
+	caCert, caKey, err := loadCA(cfg.CertificateAuthority)
+	if err != nil {
+		return false, fmt.Errorf("loading CA: %v", err)
+	}
+
+	newCertPEM, newKeyPEM, err := signClientCert(cert.Subject, caCert, caKey, cert.NotAfter.Sub(cert.NotBefore))
+	if err != nil {
+		return false, fmt.Errorf("generating client certificate: %v", err)
+	}
+
+	authInfo.ClientCertificateData = newCertPEM
+	authInfo.ClientKeyData = newKeyPEM
+	authInfo.ClientCertificate = ""
+	authInfo.ClientKey = ""
+
+	if err := WriteConfig(config, configFile); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// needsRotation reports whether less than threshold remains until cert's
+// NotAfter. A certificate whose NotBefore is still in the future is not due.
+func needsRotation(cert *x509.Certificate, threshold time.Duration) bool {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	if lifetime <= 0 {
+		return true
+	}
+	remaining := time.Until(cert.NotAfter)
+	return remaining < threshold && remaining < lifetime
+}
+
+// clientCertPEM returns the PEM-encoded client certificate referenced by
+// authInfo, reading it from disk if it was stored as a path rather than
+// inline data.
+func clientCertPEM(authInfo *api.AuthInfo) ([]byte, error) {
+	if len(authInfo.ClientCertificateData) > 0 {
+		return authInfo.ClientCertificateData, nil
+	}
+	if authInfo.ClientCertificate == "" {
+		return nil, fmt.Errorf("user has no client certificate to rotate")
+	}
+	return ioutil.ReadFile(authInfo.ClientCertificate)
+}
+
+func parseCertificatePEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in client certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// loadCA reads and parses the local CA certificate and private key used to
+// sign minikube client certificates. caCertPath is expected to sit alongside
+// a "<name>.key" file, matching how minikube lays out its CA on disk.
+func loadCA(caCertPath string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certPEM, err := ioutil.ReadFile(caCertPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM data found in CA certificate %s", caCertPath)
+	}
+	caCert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ext := filepath.Ext(caCertPath)
+	if ext == "" {
+		return nil, nil, fmt.Errorf("CA certificate path %s has no extension to derive a key path from", caCertPath)
+	}
+	keyPath := strings.TrimSuffix(caCertPath, ext) + ".key"
+	keyPEM, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM data found in CA key %s", keyPath)
+	}
+	caKey, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return caCert, caKey, nil
+}
+
+// signClientCert generates a fresh RSA key and a new client certificate with
+// the given lifetime, signed by the supplied CA, and returns both PEM-encoded.
+func signClientCert(subject pkix.Name, caCert *x509.Certificate, caKey *rsa.PrivateKey, lifetime time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notBefore := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(lifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// generateTestCA creates a self-signed CA certificate and key, writing them
+// to <dir>/ca.crt and <dir>/ca.key as RotateClientCert expects.
+func generateTestCA(t *testing.T, dir string) (caCertPath string, caCert *x509.Certificate, caKey *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating CA key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "minikubeCA"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing CA certificate: %v", err)
+	}
+
+	caCertPath = filepath.Join(dir, "ca.crt")
+	if err := ioutil.WriteFile(caCertPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("writing CA certificate: %v", err)
+	}
+	keyPath := filepath.Join(dir, "ca.key")
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600); err != nil {
+		t.Fatalf("writing CA key: %v", err)
+	}
+
+	return caCertPath, cert, key
+}
+
+// generateTestClientCert signs a client certificate with notBefore/notAfter
+// chosen so that remaining validity is a known fraction of its lifetime.
+func generateTestClientCert(t *testing.T, caCert *x509.Certificate, caKey *rsa.PrivateKey, notBefore, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating client key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "minikube-user"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("creating client certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestRotateClientCertNearExpiry(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("making temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	caCertPath, caCert, caKey := generateTestCA(t, tmpDir)
+
+	// A one-year cert with only a day left: well under a 20% threshold.
+	notBefore := time.Now().Add(-364 * 24 * time.Hour)
+	notAfter := time.Now().Add(24 * time.Hour)
+	clientCertPEM := generateTestClientCert(t, caCert, caKey, notBefore, notAfter)
+
+	config := api.NewConfig()
+	config.Clusters["minikube"] = api.NewCluster()
+	otherContextCert := generateTestClientCert(t, caCert, caKey, time.Now().Add(-time.Hour), time.Now().Add(10*365*24*time.Hour))
+	config.Clusters["other"] = api.NewCluster()
+	otherUser := api.NewAuthInfo()
+	otherUser.ClientCertificateData = otherContextCert
+	config.AuthInfos["other"] = otherUser
+	otherContext := api.NewContext()
+	otherContext.Cluster = "other"
+	otherContext.AuthInfo = "other"
+	config.Contexts["other"] = otherContext
+
+	user := api.NewAuthInfo()
+	user.ClientCertificateData = clientCertPEM
+	config.AuthInfos["minikube"] = user
+	context := api.NewContext()
+	context.Cluster = "minikube"
+	context.AuthInfo = "minikube"
+	config.Contexts["minikube"] = context
+	config.CurrentContext = "minikube"
+
+	kubeConfigFile := filepath.Join(tmpDir, "kubeconfig")
+	if err := WriteConfig(config, kubeConfigFile); err != nil {
+		t.Fatalf("seeding kubeconfig: %v", err)
+	}
+
+	cfg := &KubeConfigSetup{
+		ClusterName:          "minikube",
+		CertificateAuthority: caCertPath,
+		KubeConfigFile:       kubeConfigFile,
+	}
+
+	rotated, err := RotateClientCert(cfg, 365*24*time.Hour*20/100)
+	if err != nil {
+		t.Fatalf("RotateClientCert: %v", err)
+	}
+	if !rotated {
+		t.Fatal("expected a near-expiry certificate to be rotated")
+	}
+
+	got, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("reading rotated kubeconfig: %v", err)
+	}
+
+	newUser := got.AuthInfos["minikube"]
+	if string(newUser.ClientCertificateData) == string(clientCertPEM) {
+		t.Fatal("expected the client certificate to change")
+	}
+	newCert, err := parseCertificatePEM(newUser.ClientCertificateData)
+	if err != nil {
+		t.Fatalf("parsing rotated certificate: %v", err)
+	}
+	if !newCert.NotAfter.After(notAfter) {
+		t.Fatalf("expected rotated certificate to expire later than %v, got %v", notAfter, newCert.NotAfter)
+	}
+
+	if string(got.AuthInfos["other"].ClientCertificateData) != string(otherContextCert) {
+		t.Fatal("expected the other context's certificate to be left untouched")
+	}
+	if _, exists := got.Contexts["other"]; !exists {
+		t.Fatal("expected the other context to be preserved")
+	}
+}
+
+func TestRotateClientCertNotYetDue(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("making temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	caCertPath, caCert, caKey := generateTestCA(t, tmpDir)
+
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(365 * 24 * time.Hour)
+	clientCertPEM := generateTestClientCert(t, caCert, caKey, notBefore, notAfter)
+
+	config := api.NewConfig()
+	config.Clusters["minikube"] = api.NewCluster()
+	user := api.NewAuthInfo()
+	user.ClientCertificateData = clientCertPEM
+	config.AuthInfos["minikube"] = user
+	context := api.NewContext()
+	context.Cluster = "minikube"
+	context.AuthInfo = "minikube"
+	config.Contexts["minikube"] = context
+	config.CurrentContext = "minikube"
+
+	kubeConfigFile := filepath.Join(tmpDir, "kubeconfig")
+	if err := WriteConfig(config, kubeConfigFile); err != nil {
+		t.Fatalf("seeding kubeconfig: %v", err)
+	}
+
+	cfg := &KubeConfigSetup{
+		ClusterName:          "minikube",
+		CertificateAuthority: caCertPath,
+		KubeConfigFile:       kubeConfigFile,
+	}
+
+	rotated, err := RotateClientCert(cfg, 365*24*time.Hour*20/100)
+	if err != nil {
+		t.Fatalf("RotateClientCert: %v", err)
+	}
+	if rotated {
+		t.Fatal("expected a freshly issued certificate not to be rotated")
+	}
+}
+
+// TestRotateClientCertNamedProfile verifies RotateClientCert looks up the
+// "minikube-<profile>" entry, not ClusterName, when Profile is set.
+func TestRotateClientCertNamedProfile(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("making temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	caCertPath, caCert, caKey := generateTestCA(t, tmpDir)
+
+	notBefore := time.Now().Add(-364 * 24 * time.Hour)
+	notAfter := time.Now().Add(24 * time.Hour)
+	clientCertPEM := generateTestClientCert(t, caCert, caKey, notBefore, notAfter)
+
+	config := api.NewConfig()
+	config.Clusters["minikube-dev"] = api.NewCluster()
+	user := api.NewAuthInfo()
+	user.ClientCertificateData = clientCertPEM
+	config.AuthInfos["minikube-dev"] = user
+	context := api.NewContext()
+	context.Cluster = "minikube-dev"
+	context.AuthInfo = "minikube-dev"
+	config.Contexts["minikube-dev"] = context
+	config.CurrentContext = "minikube-dev"
+
+	kubeConfigFile := filepath.Join(tmpDir, "kubeconfig")
+	if err := WriteConfig(config, kubeConfigFile); err != nil {
+		t.Fatalf("seeding kubeconfig: %v", err)
+	}
+
+	cfg := &KubeConfigSetup{
+		Profile:              "dev",
+		CertificateAuthority: caCertPath,
+		KubeConfigFile:       kubeConfigFile,
+	}
+
+	rotated, err := RotateClientCert(cfg, 365*24*time.Hour*20/100)
+	if err != nil {
+		t.Fatalf("RotateClientCert: %v", err)
+	}
+	if !rotated {
+		t.Fatal("expected a near-expiry certificate for profile \"dev\" to be rotated")
+	}
+
+	got, err := ReadConfigOrNew(kubeConfigFile)
+	if err != nil {
+		t.Fatalf("reading rotated kubeconfig: %v", err)
+	}
+	if string(got.AuthInfos["minikube-dev"].ClientCertificateData) == string(clientCertPEM) {
+		t.Fatal("expected the client certificate to change")
+	}
+}